@@ -0,0 +1,114 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/Netwurx/routeros-api-go"
+)
+
+// Collector wraps a connected RouterOS client and knows how to gather
+// DHCP pool statistics from it. It is the single code path used by both
+// the one-shot CLI output and the Prometheus exporter, so the two modes
+// can never drift apart.
+type Collector struct {
+	Client *routeros.Client
+
+	// Router labels every PoolStat this Collector produces, so results
+	// from multiple routers can be told apart once combined.
+	Router string
+
+	// ExcludeNetworkBroadcast sizes pools with the legacy last-octet
+	// heuristic instead of full IP range math, for operators whose
+	// pools were sized around that quirk. See IPRange.legacySize.
+	ExcludeNetworkBroadcast bool
+
+	// Logger records per-pool failures. Defaults to slog.Default() if nil.
+	Logger *slog.Logger
+}
+
+// NewCollector returns a Collector backed by an already-connected client.
+func NewCollector(r *routeros.Client) *Collector {
+	return &Collector{Client: r}
+}
+
+func (c *Collector) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return slog.Default()
+}
+
+// CollectPoolStats fetches every enabled DHCP server's pool and returns
+// one PoolStat per interface with a pool assigned. A pool that fails to
+// size or enumerate doesn't abort the scrape: it's reported back with
+// its Error field set so the rest of the pools still come through.
+func (c *Collector) CollectPoolStats() ([]PoolStat, error) {
+	var dsQ routeros.Query
+	dsQ.Pairs = append(dsQ.Pairs, routeros.Pair{Key: "disabled", Value: "no", Op: "="})
+	dsQ.Proplist = []string{".id,address-pool,interface"}
+	res, err := c.Client.Query("/ip/dhcp-server/getall", dsQ)
+	if err != nil {
+		return nil, err
+	}
+
+	var pools []PoolStat
+	for _, pair := range res.SubPairs {
+		var p PoolStat
+		p.Interface = pair["interface"]
+		p.Router = c.Router
+
+		poolName := pair["address-pool"]
+		if poolName == "" {
+			continue // no pool so we can't do any sizing
+		}
+		p.Pool = poolName
+
+		rng, err := getPoolRange(c.Client, poolName)
+		if err != nil {
+			c.logger().Warn("fetching pool range failed", "router", c.Router, "pool", poolName, "error", err)
+			p.Error = err.Error()
+			pools = append(pools, p)
+			continue
+		}
+
+		size, err := sizeRange(rng, c.ExcludeNetworkBroadcast)
+		if err != nil {
+			c.logger().Warn("sizing pool failed", "router", c.Router, "pool", poolName, "error", err)
+			p.Error = err.Error()
+			pools = append(pools, p)
+			continue
+		}
+		p.Size = size
+
+		used, err := getPoolUsed(c.Client, poolName)
+		if err != nil {
+			c.logger().Warn("fetching pool usage failed", "router", c.Router, "pool", poolName, "error", err)
+			p.Error = err.Error()
+			pools = append(pools, p)
+			continue
+		}
+		p.Used = len(used)
+
+		pools = append(pools, p)
+	}
+
+	return pools, nil
+}
+
+// sizeRange sums the Size of every range in a pool, falling back to the
+// legacy last-octet heuristic when excludeNetworkBroadcast is set.
+func sizeRange(rng []IPRange, excludeNetworkBroadcast bool) (uint64, error) {
+	var size uint64
+	for _, l := range rng {
+		if excludeNetworkBroadcast {
+			size += l.legacySize()
+			continue
+		}
+		s, err := l.Size()
+		if err != nil {
+			return 0, err
+		}
+		size += s
+	}
+	return size, nil
+}
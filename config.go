@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RouterConfig describes one router to poll, as listed in a --config file.
+type RouterConfig struct {
+	Address               string `yaml:"address"`
+	Port                  int    `yaml:"port"`
+	Username              string `yaml:"username"`
+	Password              string `yaml:"password"`
+	TLS                   bool   `yaml:"tls"`
+	TLSInsecureSkipVerify bool   `yaml:"tls_insecure_skip_verify"`
+	TLSCAFile             string `yaml:"tls_ca_file"`
+	TLSServerName         string `yaml:"tls_server_name"`
+}
+
+// Config is the top-level shape of a --config file, listing every
+// router to poll.
+type Config struct {
+	Routers []RouterConfig `yaml:"routers"`
+}
+
+// loadConfig reads and parses a multi-router config file.
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config file: %w", err)
+	}
+
+	if len(cfg.Routers) == 0 {
+		return nil, fmt.Errorf("config file %s lists no routers", path)
+	}
+
+	for i, rt := range cfg.Routers {
+		if rt.Address == "" {
+			return nil, fmt.Errorf("routers[%d] is missing an address", i)
+		}
+		if rt.Port == 0 {
+			if rt.TLS {
+				cfg.Routers[i].Port = 8729
+			} else {
+				cfg.Routers[i].Port = 8728
+			}
+		}
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// promCollector implements prometheus.Collector, scraping the router on
+// every call to Collect so Prometheus always sees a live snapshot.
+type promCollector struct {
+	collector *Collector
+}
+
+var (
+	poolUsedDesc = prometheus.NewDesc(
+		"mtdhcp_pool_used_count",
+		"Number of leased addresses in a DHCP pool",
+		[]string{"interface", "pool", "router"}, nil,
+	)
+	poolSizeDesc = prometheus.NewDesc(
+		"mtdhcp_pool_size",
+		"Total number of addresses in a DHCP pool",
+		[]string{"interface", "pool", "router"}, nil,
+	)
+	poolFreeDesc = prometheus.NewDesc(
+		"mtdhcp_pool_free",
+		"Number of unleased addresses in a DHCP pool",
+		[]string{"interface", "pool", "router"}, nil,
+	)
+	poolErrorDesc = prometheus.NewDesc(
+		"mtdhcp_pool_error",
+		"Whether the last scrape of this pool failed (1) or succeeded (0)",
+		[]string{"interface", "pool", "router"}, nil,
+	)
+	scrapeSuccessDesc = prometheus.NewDesc(
+		"mtdhcp_scrape_success",
+		"Whether the last scrape of the router succeeded (1) or not (0)",
+		[]string{"router"}, nil,
+	)
+	scrapeDurationDesc = prometheus.NewDesc(
+		"mtdhcp_scrape_duration_seconds",
+		"Duration of the last scrape of the router",
+		[]string{"router"}, nil,
+	)
+)
+
+// newPromCollector returns a prometheus.Collector that scrapes pool
+// stats through c on every Collect call, labelling metrics with c.Router.
+func newPromCollector(c *Collector) *promCollector {
+	return &promCollector{collector: c}
+}
+
+func (p *promCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolUsedDesc
+	ch <- poolSizeDesc
+	ch <- poolFreeDesc
+	ch <- poolErrorDesc
+	ch <- scrapeSuccessDesc
+	ch <- scrapeDurationDesc
+}
+
+func (p *promCollector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+	pools, err := p.collector.CollectPoolStats()
+	duration := time.Since(start).Seconds()
+	router := p.collector.Router
+
+	success := 1.0
+	if err != nil {
+		success = 0.0
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success, router)
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, duration, router)
+	if err != nil {
+		return
+	}
+
+	for _, pool := range pools {
+		if pool.Error != "" {
+			ch <- prometheus.MustNewConstMetric(poolErrorDesc, prometheus.GaugeValue, 1, pool.Interface, pool.Pool, router)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(poolErrorDesc, prometheus.GaugeValue, 0, pool.Interface, pool.Pool, router)
+		ch <- prometheus.MustNewConstMetric(poolUsedDesc, prometheus.GaugeValue, float64(pool.Used), pool.Interface, pool.Pool, router)
+		ch <- prometheus.MustNewConstMetric(poolSizeDesc, prometheus.GaugeValue, float64(pool.Size), pool.Interface, pool.Pool, router)
+		ch <- prometheus.MustNewConstMetric(poolFreeDesc, prometheus.GaugeValue, float64(pool.Size-uint64(pool.Used)), pool.Interface, pool.Pool, router)
+	}
+}
+
+// serveMetrics registers c and blocks serving Prometheus metrics on
+// listen until the process is killed.
+func serveMetrics(listen, metricsPath string, c *Collector) error {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(newPromCollector(c))
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	return http.ListenAndServe(listen, mux)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Size returns the inclusive count of addresses between Min and Max,
+// computed with real IP arithmetic so ranges that cross octet (or, for
+// IPv6, hextet) boundaries are sized correctly. Min and Max must be the
+// same address family.
+func (r IPRange) Size() (uint64, error) {
+	min, err := netip.ParseAddr(r.Min)
+	if err != nil {
+		return 0, fmt.Errorf("invalid range start %q: %w", r.Min, err)
+	}
+	max, err := netip.ParseAddr(r.Max)
+	if err != nil {
+		return 0, fmt.Errorf("invalid range end %q: %w", r.Max, err)
+	}
+	if min.Is4() != max.Is4() {
+		return 0, fmt.Errorf("range %s-%s mixes address families", r.Min, r.Max)
+	}
+
+	minInt := new(big.Int).SetBytes(min.AsSlice())
+	maxInt := new(big.Int).SetBytes(max.AsSlice())
+	if maxInt.Cmp(minInt) < 0 {
+		return 0, fmt.Errorf("range %s-%s has end before start", r.Min, r.Max)
+	}
+
+	size := new(big.Int).Sub(maxInt, minInt)
+	size.Add(size, big.NewInt(1))
+	if !size.IsUint64() {
+		return 0, fmt.Errorf("range %s-%s has %s addresses, too large to report as a uint64", r.Min, r.Max, size)
+	}
+
+	return size.Uint64(), nil
+}
+
+// legacySize replicates the pre-CIDR-math heuristic: it only looks at
+// the last octet of an IPv4 range, so it silently reports a bogus size
+// for anything that crosses a /24 boundary. It's kept behind
+// -exclude-network-broadcast for operators whose pools (and alerting
+// thresholds) were sized around this quirk.
+func (r IPRange) legacySize() uint64 {
+	minparts := strings.Split(r.Min, ".")
+	maxparts := strings.Split(r.Max, ".")
+	if len(minparts) != 4 || len(maxparts) != 4 {
+		return 0
+	}
+	min, _ := strconv.Atoi(minparts[3])
+	max, _ := strconv.Atoi(maxparts[3])
+	if max < min {
+		return 0
+	}
+	return uint64(max - min)
+}
@@ -0,0 +1,103 @@
+package main
+
+import "testing"
+
+func TestIPRangeSize(t *testing.T) {
+	cases := []struct {
+		name    string
+		r       IPRange
+		want    uint64
+		wantErr bool
+	}{
+		{
+			name: "within a single octet",
+			r:    IPRange{Min: "10.0.0.1", Max: "10.0.0.254"},
+			want: 254,
+		},
+		{
+			name: "crosses an octet boundary",
+			r:    IPRange{Min: "10.0.0.1", Max: "10.0.1.1"},
+			want: 257,
+		},
+		{
+			name: "crosses a /22 boundary",
+			r:    IPRange{Min: "10.0.0.1", Max: "10.0.3.254"},
+			want: 1022,
+		},
+		{
+			name: "ipv6 range",
+			r:    IPRange{Min: "2001:db8::1", Max: "2001:db8::ffff"},
+			want: 65535,
+		},
+		{
+			name:    "mixed address families rejected",
+			r:       IPRange{Min: "10.0.0.1", Max: "::1"},
+			wantErr: true,
+		},
+		{
+			name:    "end before start rejected",
+			r:       IPRange{Min: "10.0.0.254", Max: "10.0.0.1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid address rejected",
+			r:       IPRange{Min: "not-an-ip", Max: "10.0.0.1"},
+			wantErr: true,
+		},
+		{
+			name:    "too large to fit in a uint64",
+			r:       IPRange{Min: "::", Max: "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.r.Size()
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("Size() = %d, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Size() returned unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Fatalf("Size() = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestIPRangeLegacySize(t *testing.T) {
+	cases := []struct {
+		name string
+		r    IPRange
+		want uint64
+	}{
+		{
+			name: "within a single octet",
+			r:    IPRange{Min: "10.0.0.1", Max: "10.0.0.254"},
+			want: 253,
+		},
+		{
+			name: "crossing a /24 boundary is not supported",
+			r:    IPRange{Min: "10.0.0.1", Max: "10.0.1.1"},
+			want: 0,
+		},
+		{
+			name: "end before start",
+			r:    IPRange{Min: "10.0.0.254", Max: "10.0.0.1"},
+			want: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.r.legacySize(); got != c.want {
+				t.Fatalf("legacySize() = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
@@ -11,24 +11,13 @@ import (
 	"strings"
 )
 
-type jsError struct {
-	Error string
-}
-
 type PoolStat struct {
 	Interface string
+	Pool      string
+	Router    string
 	Used      int
-	Size      int
-}
-
-func jserror(msg string) {
-	e := jsError{msg}
-	m, err := json.Marshal(e)
-	if err != nil {
-		panic(err)
-	}
-	os.Stderr.Write(m)
-	os.Exit(1)
+	Size      uint64
+	Error     string `json:",omitempty"`
 }
 
 type IPRange struct {
@@ -103,10 +92,41 @@ func main() {
 	flag.Usage = usage
 	js := false
 	flag.BoolVar(&js, "json", false, "display output in json format")
-	port := 8729
-	flag.IntVar(&port, "port", 8728, "RouterOS API port number")
+	port := 0
+	flag.IntVar(&port, "port", 0, "RouterOS API port number (default 8728, or 8729 with -tls)")
+	useTLS := false
+	flag.BoolVar(&useTLS, "tls", false, "connect using the RouterOS api-ssl service")
+	tlsInsecureSkipVerify := false
+	flag.BoolVar(&tlsInsecureSkipVerify, "tls-insecure-skip-verify", false, "skip verification of the router's TLS certificate")
+	tlsCAFile := ""
+	flag.StringVar(&tlsCAFile, "tls-ca-file", "", "PEM file of CA certificates to verify the router's TLS certificate against")
+	tlsServerName := ""
+	flag.StringVar(&tlsServerName, "tls-server-name", "", "server name to verify in the router's TLS certificate, if different from the router address")
+	listen := ""
+	flag.StringVar(&listen, "listen", "", "run as a Prometheus exporter, listening on this address (e.g. :9436), instead of printing once and exiting")
+	metricsPath := "/metrics"
+	flag.StringVar(&metricsPath, "metrics-path", "/metrics", "HTTP path to expose Prometheus metrics on, when -listen is set")
+	excludeNetworkBroadcast := false
+	flag.BoolVar(&excludeNetworkBroadcast, "exclude-network-broadcast", false, "size pools using the legacy last-octet heuristic instead of full IP range math (back-compat)")
+	configPath := ""
+	flag.StringVar(&configPath, "config", "", "path to a config file listing multiple routers to poll, instead of a single router-address argument")
+	workers := 4
+	flag.IntVar(&workers, "workers", 4, "maximum number of routers to poll concurrently, when -config is set")
+	logLevel := "info"
+	flag.StringVar(&logLevel, "log-level", "info", "log level: debug, info, warn, or error")
+	logFormat := "text"
+	flag.StringVar(&logFormat, "log-format", "text", "log format: text or json")
 	flag.Parse()
 
+	logger := newLogger(logLevel, logFormat)
+
+	// a config file polls any number of routers instead of the single
+	// router-address argument
+	if configPath != "" {
+		runMultiRouter(logger, configPath, workers, excludeNetworkBroadcast, js)
+		return
+	}
+
 	// check for host
 	args := flag.Args()
 	if len(args) != 1 {
@@ -117,111 +137,70 @@ func main() {
 	// check for login credentials
 	username := os.Getenv("MT_USERNAME")
 	if username == "" {
-		if js {
-			jserror("MT_USERNAME is empty or not set")
-		}
-		fmt.Fprintln(os.Stderr, "Error: MT_USERNAME is empty or not set")
+		logger.Error("MT_USERNAME is empty or not set")
 		usage()
 	}
 
 	// don't check for password because missing password is valid (just not good)
 	password := os.Getenv("MT_PASSWORD")
 
+	if port == 0 {
+		if useTLS {
+			port = 8729
+		} else {
+			port = 8728
+		}
+	}
+
 	// try to connect to router
 	hp := host + ":" + strconv.Itoa(port)
-	r, err := routeros.New(hp)
+	r, err := dialRouter(hp, useTLS, tlsInsecureSkipVerify, tlsCAFile, tlsServerName)
 	if err != nil {
-		if js {
-			jserror("Invalid address for router")
-		}
-		fmt.Fprintf(os.Stderr, "Invalid address for router: %s\n", err)
+		logger.Error("error dialing router", "router", host, "error", err)
 		os.Exit(1)
 	}
 	err = r.Connect(username, password)
 	if err != nil {
-		if js {
-			jserror("Error connecting to router")
-		}
-		fmt.Fprintf(os.Stderr, "Error connecting to router: %s\n", err)
-		os.Exit(1)
-	}
-
-	// get all dhcp servers
-	var dsQ routeros.Query
-	dsQ.Pairs = append(dsQ.Pairs, routeros.Pair{Key: "disabled", Value: "no", Op: "="})
-	dsQ.Proplist = []string{".id,address-pool,interface"}
-	res, err := r.Query("/ip/dhcp-server/getall", dsQ)
-	if err != nil {
-		if js {
-			jserror("Error fetching list of dhcp interfaces from router")
-		}
-		fmt.Fprintf(os.Stderr, "Error fetching list of dhcp interfaces: %s\n", err)
+		logger.Error("error connecting to router", "router", host, "error", err)
 		os.Exit(1)
 	}
 
-	if !js {
-		fmt.Println("Interface\tUsed\tFree")
-	}
-	var pools []PoolStat
-	for _, pair := range res.SubPairs {
-		var p PoolStat
-		p.Interface = pair["interface"]
-
-		// get pool range, find matching subnet
-		poolName := pair["address-pool"]
-		if poolName == "" {
-			continue // no pool so we can't do any sizing
-		}
-
-		pool, err := getPoolRange(r, poolName)
-		if err != nil {
-			if js {
-				jserror("Error fetching pool information for pool " + poolName)
-			}
-			fmt.Fprintf(os.Stderr, "Error fetching pool information: %s\n", err)
-			os.Exit(1)
-		}
-
-		// calculate size of pool. we don't need cidr math because crossing .0 and .255 leads to
-		// user issues so nobody uses those addrs
-		size := 0
-		for _, l := range pool {
-			minparts := strings.Split(l.Min, ".")
-			min, _ := strconv.Atoi(minparts[3])
-			maxparts := strings.Split(l.Max, ".")
-			max, _ := strconv.Atoi(maxparts[3])
+	collector := NewCollector(r)
+	collector.Router = host
+	collector.ExcludeNetworkBroadcast = excludeNetworkBroadcast
+	collector.Logger = logger
 
-			size += max - min
-		}
-		p.Size = size
-
-		used, err := getPoolUsed(r, poolName)
-		if err != nil {
-			if js {
-				jserror("Error fetching pool usage for pool " + poolName)
-			}
-			fmt.Fprintf(os.Stderr, "Error fetching pool usage for pool %s: %s\n", poolName, err)
+	// exporter mode: serve metrics forever instead of printing once
+	if listen != "" {
+		logger.Info("starting exporter", "listen", listen, "metrics-path", metricsPath)
+		if err := serveMetrics(listen, metricsPath, collector); err != nil {
+			logger.Error("error serving metrics", "error", err)
 			os.Exit(1)
 		}
-		p.Used = len(used)
-
-		if !js {
-			fmt.Printf("%s\t%12d\t%4d\n", p.Interface, p.Used, (p.Size - p.Used))
-		}
+		return
+	}
 
-		pools = append(pools, p)
+	pools, err := collector.CollectPoolStats()
+	if err != nil {
+		logger.Error("error collecting pool statistics", "router", host, "error", err)
+		os.Exit(1)
 	}
 
 	if js {
 		j, err := json.Marshal(pools)
 		if err != nil {
-			jserror("Error encoding json representation of pools")
+			logger.Error("error encoding json representation of pools", "error", err)
+			os.Exit(1)
 		}
 		fmt.Printf("%s", j)
+	} else {
+		fmt.Println("Interface\tUsed\tFree")
+		for _, p := range pools {
+			if p.Error != "" {
+				fmt.Printf("%s\tERROR: %s\n", p.Interface, p.Error)
+				continue
+			}
+			fmt.Printf("%s\t%12d\t%4d\n", p.Interface, p.Used, p.Size-uint64(p.Used))
+		}
 	}
-	/*
-		what i was going to do before stopping for sleep:
-		loop through pools, check usage, return info, append to PoolStats
-		after that we format stuff for output to user
-	*/
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// runMultiRouter polls every router listed in the config file at
+// configPath, bounding concurrency to workers, and prints the combined
+// results as JSON, or as a table like the single-host one with an extra
+// leading Router column.
+func runMultiRouter(logger *slog.Logger, configPath string, workers int, excludeNetworkBroadcast bool, js bool) {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		logger.Error("error loading config", "config", configPath, "error", err)
+		os.Exit(1)
+	}
+
+	var (
+		mu    sync.Mutex
+		pools []PoolStat
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, workers)
+	)
+
+	for _, rt := range cfg.Routers {
+		wg.Add(1)
+		go func(rt RouterConfig) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			stats, err := collectFromRouter(logger, rt, excludeNetworkBroadcast)
+			if err != nil {
+				logger.Error("error polling router", "router", rt.Address, "error", err)
+				stats = []PoolStat{{Router: rt.Address, Error: err.Error()}}
+			}
+
+			mu.Lock()
+			pools = append(pools, stats...)
+			mu.Unlock()
+		}(rt)
+	}
+	wg.Wait()
+
+	if js {
+		j, err := json.Marshal(pools)
+		if err != nil {
+			logger.Error("error encoding json representation of pools", "error", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s", j)
+		return
+	}
+
+	fmt.Println("Router\tInterface\tUsed\tFree")
+	for _, p := range pools {
+		if p.Error != "" {
+			fmt.Printf("%s\t%s\tERROR: %s\n", p.Router, p.Interface, p.Error)
+			continue
+		}
+		fmt.Printf("%s\t%s\t%12d\t%4d\n", p.Router, p.Interface, p.Used, p.Size-uint64(p.Used))
+	}
+}
+
+// collectFromRouter dials, connects to, and scrapes a single configured
+// router, tagging every returned PoolStat with its address.
+func collectFromRouter(logger *slog.Logger, rt RouterConfig, excludeNetworkBroadcast bool) ([]PoolStat, error) {
+	hp := rt.Address + ":" + strconv.Itoa(rt.Port)
+	r, err := dialRouter(hp, rt.TLS, rt.TLSInsecureSkipVerify, rt.TLSCAFile, rt.TLSServerName)
+	if err != nil {
+		return nil, fmt.Errorf("dialing router: %w", err)
+	}
+
+	if err := r.Connect(rt.Username, rt.Password); err != nil {
+		return nil, fmt.Errorf("connecting to router: %w", err)
+	}
+
+	collector := NewCollector(r)
+	collector.Router = rt.Address
+	collector.ExcludeNetworkBroadcast = excludeNetworkBroadcast
+	collector.Logger = logger
+
+	return collector.CollectPoolStats()
+}
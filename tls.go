@@ -0,0 +1,54 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/Netwurx/routeros-api-go"
+)
+
+// tlsConfig builds the *tls.Config used to dial the RouterOS api-ssl
+// service, from either the --tls-* flags or a router's TLS settings in a
+// --config file.
+func tlsConfig(insecureSkipVerify bool, caFile, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: insecureSkipVerify,
+		ServerName:         serverName,
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading TLS CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+// dialRouter connects to a RouterOS API service at hp, over TLS when
+// useTLS is set.
+func dialRouter(hp string, useTLS bool, insecureSkipVerify bool, caFile, serverName string) (*routeros.Client, error) {
+	r, err := routeros.New(hp)
+	if err != nil {
+		return nil, err
+	}
+	if !useTLS {
+		return r, nil
+	}
+
+	cfg, err := tlsConfig(insecureSkipVerify, caFile, serverName)
+	if err != nil {
+		return nil, err
+	}
+	r.TLSConfig = cfg
+
+	return r, nil
+}